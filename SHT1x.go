@@ -4,41 +4,53 @@
 // This package reads Humidity and Temperature from a Sensirion SHT1x and SHT7x sensors. It has been tested
 // with an SHT71.
 
-// It is meant to be used in a Raspberry Pi.
-
-// Requires root privileges, therefore, to run this module you need to run your script as root.
+// It talks to the sensor over any Bus implementation; see the rpi subpackage
+// for Raspberry Pi GPIO and the mock subpackage for testing without hardware.
 
 // example usage:
-//		 sht := SHT1x.New(rpi.GPIO_P1_11, rpi.GPIO_P1_07)
+//		 bus, _ := rpi.New(17, 4) // data on GPIO17 (P1 pin 11), clock on GPIO4 (P1 pin 7)
+//		 sht := SHT1x.New(bus)
 
-//		 temp := sht.ReadTemperature()
+//		 temp, err := sht.ReadTemperature()
 //		 fmt.Printf("temp (°C): %.2f\n", temp)
 
-//		 humid := sht.ReadHumidity()
+//		 humid, err := sht.ReadHumidity()
 //		 fmt.Printf("Humid (rel%%): %.2f\n", humid)
 
-//		 temp2, humid2 := sht.ReadTempAndHumidity()
+//		 temp2, humid2, dewPoint, err := sht.ReadTempAndHumidity()
 
 //		 fmt.Printf("temp (°C): %.2f\n", temp2)
 //		 fmt.Printf("Humid (rel%%): %.2f\n", humid2)
+//		 fmt.Printf("Dew point (°C): %.2f\n", dewPoint)
 
 //		 sht.CleanUp()
 
 package SHT1x
 
 import (
+	"context"
 	"log"
-	"time"
-
-	"github.com/griffina/gpio"
-	_ "github.com/griffina/gpio/rpi"
+	"sync"
 )
 
 // Sensor type called SHT1x after the Sht1x class in rpisht1x.
 // Can be used with SHT71/SHT75/SHT15/SHT11/SHT10
 type SHT1x struct {
-	dataPin  gpio.Pin
-	clockPin gpio.Pin
+	bus Bus
+
+	// mu guards every bus transaction; the sensor's single-wire protocol
+	// cannot be shared between concurrent callers.
+	mu sync.Mutex
+
+	// statusReg mirrors the sensor's status register (0 on power-on) and
+	// seeds the CRC-8 accumulator for each transaction.
+	statusReg uint8
+	// crc accumulates the running CRC-8 over the command and data bytes
+	// of the transaction currently in flight.
+	crc uint8
+	// d1 is the temperature offset coefficient for the supply voltage
+	// passed to NewWithVoltage.
+	d1 float32
 }
 
 // Consts from the datasheet for comunicating withe the sensor
@@ -47,89 +59,160 @@ type SHT1x struct {
 // If using SHT1x the refer to:
 // http://www.sensirion.com/fileadmin/user_upload/customers/sensirion/Dokumente/Humidity/Sensirion_Humidity_SHT1x_Datasheet_V5.pdf
 const (
-	d1 float32 = -40.1
-	d2 float32 = 0.01
+	d2    float32 = 0.01 // for 14 Bit
+	d2Low float32 = 0.04 // for 12 Bit
 
-	c1 float32 = -2.0468    // for 12 Bit
-	c2 float32 = 0.0367     // for 12 Bit
-	c3 float32 = -1.5955E-6 // for 12 Bit
-	t1 float32 = 0.01       // for 12 Bit @ 5V
-	t2 float32 = 0.00008    // for 12 Bit @ 5V
+	c1 float32 = -2.0468    // RH, for 12 Bit
+	c2 float32 = 0.0367     // RH, for 12 Bit
+	c3 float32 = -1.5955e-6 // RH, for 12 Bit
+	t1 float32 = 0.01       // RH temp compensation, for 12 Bit @ 5V
+	t2 float32 = 0.00008    // RH temp compensation, for 12 Bit @ 5V
+
+	c1Low float32 = -2.0468    // RH, for 8 Bit
+	c2Low float32 = 0.5872     // RH, for 8 Bit
+	c3Low float32 = -4.0845e-4 // RH, for 8 Bit
+	t1Low float32 = 0.01       // RH temp compensation, for 8 Bit @ 5V
+	t2Low float32 = 0.00128    // RH temp compensation, for 8 Bit @ 5V
 
 	//bin for "00000101"
 	humidCmd uint8 = 5
 	//bin for "00000011"
 	tempCmd uint8 = 3
+	//bin for "00000110"
+	writeStatusCmd uint8 = 6
+	//bin for "00000111"
+	readStatusCmd uint8 = 7
 )
 
-// Create a new sensor, supplying the clock and data pins,
-// returns a pointer to a sensor
-func New(P1_dataPin, P1_clockPin int) *SHT1x {
-	//create two gpio pins
-
-	pinData, pin1_err := gpio.OpenPin(P1_dataPin, gpio.ModeOutput)
-
-	if pin1_err != nil {
-		log.Println("error opening data pin:", P1_dataPin, pin1_err)
-	}
-
-	pinClock, pin2_err := gpio.OpenPin(P1_clockPin, gpio.ModeOutput)
-
-	if pin2_err != nil {
-		log.Println("error opening clock pin:", P1_clockPin, pin2_err)
-	}
+// Create a new sensor, talking to it over the given Bus.
+// Assumes a 5V supply; use NewWithVoltage if the sensor is powered
+// differently, since the temperature formula's offset coefficient
+// depends on VDD.
+func New(bus Bus) *SHT1x {
+	return NewWithVoltage(bus, VDD_5V)
+}
 
-	return &SHT1x{dataPin: pinData, clockPin: pinClock}
+// NewWithVoltage creates a new sensor like New, but selects the
+// temperature offset coefficient (d1) for the given supply voltage
+// instead of assuming 5V.
+func NewWithVoltage(bus Bus, v Voltage) *SHT1x {
+	return &SHT1x{bus: bus, d1: d1ForVoltage(v)}
 }
 
 // Reads th humidity from the sensor and returns the relative humidity %
 // to do this the temperature also has to be read
-func (sht *SHT1x) ReadHumidity() float32 {
-	// not interested in the temp returned, but the
+func (sht *SHT1x) ReadHumidity() (float32, error) {
+	return sht.ReadHumidityCtx(context.Background())
+}
+
+// ReadHumidityCtx is ReadHumidity, aborting the poll for a ready
+// measurement if ctx is cancelled or deadlined.
+func (sht *SHT1x) ReadHumidityCtx(ctx context.Context) (float32, error) {
+	sht.mu.Lock()
+	defer sht.mu.Unlock()
+
+	// not interested in the temp or dew point returned, but the
 	// temp is needed to read the relative humidity
-	_, humidity := sht.ReadTempAndHumidity()
-	return humidity
+	_, humidity, _, err := sht.readTempAndHumidity(ctx)
+	return humidity, err
 }
 
 // Read the temperature from the sensor and returns °C
 // Like rpisht1x,:
-//  "I deliberately will not implement read_temperature_F because I believe in the
-//   in the Metric System (http://en.wikipedia.org/wiki/Metric_system)"
-func (sht *SHT1x) ReadTemperature() float32 {
+//
+//	"I deliberately will not implement read_temperature_F because I believe in the
+//	 in the Metric System (http://en.wikipedia.org/wiki/Metric_system)"
+func (sht *SHT1x) ReadTemperature() (float32, error) {
+	return sht.ReadTemperatureCtx(context.Background())
+}
+
+// ReadTemperatureCtx is ReadTemperature, aborting the poll for a ready
+// measurement if ctx is cancelled or deadlined.
+func (sht *SHT1x) ReadTemperatureCtx(ctx context.Context) (float32, error) {
+	sht.mu.Lock()
+	defer sht.mu.Unlock()
+	return sht.readTemperature(ctx)
+}
+
+func (sht *SHT1x) readTemperature(ctx context.Context) (float32, error) {
+	bits, scale := int16(14), d2
+	if sht.lowResolution() {
+		bits, scale = 12, d2Low
+	}
 
 	sht.sendCommand(tempCmd)
-	sht.waitForResult()
-	val := sht.getData16()
-	sht.skipCRC()
+	if err := sht.waitForResult(ctx); err != nil {
+		sht.reset()
+		return 0, err
+	}
+	val := sht.getData16(bits)
+	if err := sht.checkCRC(); err != nil {
+		return 0, err
+	}
 	// Maths from data sheet
-	return (float32(val) * d2) + d1
+	return (float32(val) * scale) + sht.d1, nil
+}
+
+// Read the temperature in °C, relative humidity and dew point (both in
+// °C) from the sensor and returns them.
+func (sht *SHT1x) ReadTempAndHumidity() (temp, humidity, dewPoint float32, err error) {
+	return sht.ReadTempAndHumidityCtx(context.Background())
 }
 
-// Read the temperature in °C and relative humidity from the sensor and returns
-func (sht *SHT1x) ReadTempAndHumidity() (temp, humidity float32) {
-	temp = sht.ReadTemperature()
+// ReadTempAndHumidityCtx is ReadTempAndHumidity, aborting the poll for a
+// ready measurement if ctx is cancelled or deadlined.
+func (sht *SHT1x) ReadTempAndHumidityCtx(ctx context.Context) (temp, humidity, dewPoint float32, err error) {
+	sht.mu.Lock()
+	defer sht.mu.Unlock()
+	return sht.readTempAndHumidity(ctx)
+}
+
+func (sht *SHT1x) readTempAndHumidity(ctx context.Context) (temp, humidity, dewPoint float32, err error) {
+	temp, err = sht.readTemperature(ctx)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	bits, rc1, rc2, rc3, rt1, rt2 := int16(12), c1, c2, c3, t1, t2
+	if sht.lowResolution() {
+		bits, rc1, rc2, rc3, rt1, rt2 = 8, c1Low, c2Low, c3Low, t1Low, t2Low
+	}
 
 	sht.sendCommand(humidCmd)
-	sht.waitForResult()
-	val := sht.getData16()
+	if err = sht.waitForResult(ctx); err != nil {
+		sht.reset()
+		return 0, 0, 0, err
+	}
+	val := sht.getData16(bits)
 
-	sht.skipCRC()
+	if err = sht.checkCRC(); err != nil {
+		return 0, 0, 0, err
+	}
 
 	floatVal := float32(val)
 	// Maths from data sheet
-	linearHumidity := c1 + c2*floatVal + c3*floatVal*floatVal
+	linearHumidity := rc1 + rc2*floatVal + rc3*floatVal*floatVal
 
-	humidity = (temp-25.0)*(t1+t2*floatVal) + linearHumidity
-	return temp, humidity
+	humidity = (temp-25.0)*(rt1+rt2*floatVal) + linearHumidity
+	dewPoint = dewPointFromTempAndHumidity(temp, humidity)
+	return temp, humidity, dewPoint, nil
 }
 
-//Reset the sensor
+// Reset the sensor
 func (sht *SHT1x) Reset() {
-	sht.dataPin.SetMode(gpio.ModeOutput)
-	sht.clockPin.SetMode(gpio.ModeOutput)
-	sht.dataPin.Set()
+	sht.mu.Lock()
+	defer sht.mu.Unlock()
+	sht.reset()
+}
+
+func (sht *SHT1x) reset() {
+	sht.bus.Data().SetOutput()
+	sht.bus.Clock().SetOutput()
+	sht.bus.Data().High()
 
-	for i := 0; i < 10; i++ {
+	// Datasheet connection reset sequence: DATA held high for 9 SCK
+	// cycles.
+	for i := 0; i < 9; i++ {
 		sht.clockTick(true)
 		sht.clockTick(false)
 	}
@@ -137,8 +220,10 @@ func (sht *SHT1x) Reset() {
 
 // Set the gpio pins back to input for safety
 func (sht *SHT1x) CleanUp() {
-	sht.dataPin.SetMode(gpio.ModeInput)
-	sht.clockPin.SetMode(gpio.ModeInput)
+	sht.mu.Lock()
+	defer sht.mu.Unlock()
+	sht.bus.Data().SetInput()
+	sht.bus.Clock().SetInput()
 }
 
 ///// Private methods below
@@ -150,7 +235,7 @@ func (sht *SHT1x) shiftIn(numberofBits int16) uint16 {
 
 		sht.clockTick(true)
 
-		binVal := sht.dataPin.Get()
+		binVal := sht.bus.Data().Read()
 
 		if binVal == true {
 			ret = (ret * 2) + 1
@@ -166,35 +251,28 @@ func (sht *SHT1x) shiftIn(numberofBits int16) uint16 {
 // Send the a command to the sensor and process the ACK
 func (sht *SHT1x) sendCommand(command uint8) {
 
-	sht.dataPin.SetMode(gpio.ModeOutput)
-	sht.clockPin.SetMode(gpio.ModeOutput)
+	// Start a fresh CRC-8 transaction seeded with the (reversed) status
+	// register, as the datasheet's checksum example does.
+	sht.crc = crc8(reverseBits(sht.statusReg), command)
 
-	sht.dataPin.Set()
+	sht.bus.Data().SetOutput()
+	sht.bus.Clock().SetOutput()
+
+	sht.bus.Data().High()
 	sht.clockTick(true)
-	sht.dataPin.Clear()
+	sht.bus.Data().Low()
 	sht.clockTick(false)
 	sht.clockTick(true)
-	sht.dataPin.Set()
+	sht.bus.Data().High()
 	sht.clockTick(false)
 
-	var i uint8
-	for i = 0; i < 8; i++ {
-		var bitVal uint8
-		bitVal = command & (1 << (7 - i))
-		if bitVal != 0 {
-			sht.dataPin.Set()
-		} else {
-			sht.dataPin.Clear()
-		}
-		sht.clockTick(true)
-		sht.clockTick(false)
-	}
+	sht.writeByte(command)
 
 	sht.clockTick(true)
 
-	sht.dataPin.SetMode(gpio.ModeInput)
+	sht.bus.Data().SetInput()
 
-	ack := sht.dataPin.Get()
+	ack := sht.bus.Data().Read()
 
 	if ack != false {
 		log.Println("Nack 1 false, in sent command")
@@ -202,7 +280,7 @@ func (sht *SHT1x) sendCommand(command uint8) {
 
 	sht.clockTick(false)
 
-	ack = sht.dataPin.Get()
+	ack = sht.bus.Data().Read()
 
 	if ack != true {
 		log.Println("Nack 2 true, in sent command")
@@ -210,66 +288,112 @@ func (sht *SHT1x) sendCommand(command uint8) {
 
 }
 
+// writeByte clocks one byte onto the data line, MSB first. Used both for
+// the command byte in sendCommand and for the data byte in
+// WriteStatusRegister.
+func (sht *SHT1x) writeByte(b uint8) {
+	var i uint8
+	for i = 0; i < 8; i++ {
+		if b&(1<<(7-i)) != 0 {
+			sht.bus.Data().High()
+		} else {
+			sht.bus.Data().Low()
+		}
+		sht.clockTick(true)
+		sht.clockTick(false)
+	}
+}
+
 // if High == true set the clock line high
 // else set it low
-// then wait 100 nanoseconds
+// then wait one clock half-period
 func (sht *SHT1x) clockTick(high bool) {
 	if high {
-		sht.clockPin.Set()
+		sht.bus.Clock().High()
 	} else {
-		sht.clockPin.Clear()
+		sht.bus.Clock().Low()
 	}
-	time.Sleep(100 * time.Nanosecond)
+	sht.bus.DelayShort()
 }
 
-// wait for the data bin to become high to signal the data is ready
-func (sht *SHT1x) waitForResult() {
-	var i int16
-	var ack bool
-	sht.dataPin.SetMode(gpio.ModeInput)
+// wait for the data bin to become high to signal the data is ready,
+// aborting early if ctx is cancelled or deadlined
+func (sht *SHT1x) waitForResult(ctx context.Context) error {
+	sht.bus.Data().SetInput()
 
-	for i = 0; i < 100; i++ {
-		time.Sleep(10 * time.Millisecond)
-		ack = sht.dataPin.Get()
+	for i := 0; i < 100; i++ {
+		if ctx.Err() != nil {
+			return &TimeoutError{Op: "waiting for measurement"}
+		}
+		sht.bus.DelayLong()
 
-		if ack == false {
-			return
+		if ack := sht.bus.Data().Read(); ack == false {
+			return nil
 		}
 	}
-	log.Println("Wait exhausted")
+	return &TimeoutError{Op: "waiting for measurement"}
 }
 
-// get the data from the pins
-func (sht *SHT1x) getData16() uint16 {
-	var val uint16
-	//// Get the most significant bits
-	sht.dataPin.SetMode(gpio.ModeInput)
-	sht.clockPin.SetMode(gpio.ModeOutput)
-
-	val = sht.shiftIn(8)
-	val *= 256
+// get the data from the pins. totalBits is how many of the returned bits
+// are significant (14/12 for temperature, 12/8 for humidity, depending on
+// the status register's resolution bit, see lowResolution). For more than
+// 8 bits a full 8-bit MSB and LSB are clocked in, whatever the
+// resolution. For 8 bits or fewer (the 8-bit low-resolution humidity
+// reading, and the status register in ReadStatusRegister) only a single
+// byte is clocked in and returned.
+func (sht *SHT1x) getData16(totalBits int16) uint16 {
+	//// Get the most significant byte
+	sht.bus.Data().SetInput()
+	sht.bus.Clock().SetOutput()
+
+	msb := sht.shiftIn(8)
+	sht.crc = crc8(sht.crc, uint8(msb))
+
+	if totalBits <= 8 {
+		return msb
+	}
 
 	//// Send the required ack
-	sht.dataPin.SetMode(gpio.ModeOutput)
-	sht.dataPin.Set()
-	sht.dataPin.Clear()
+	sht.bus.Data().SetOutput()
+	sht.bus.Data().High()
+	sht.bus.Data().Low()
 
 	sht.clockTick(true)
 	sht.clockTick(false)
 
-	//// Get the least significant bits
-	sht.dataPin.SetMode(gpio.ModeInput)
-	val |= sht.shiftIn(8)
+	//// Get the least significant byte
+	sht.bus.Data().SetInput()
+	lsb := sht.shiftIn(8)
+	sht.crc = crc8(sht.crc, uint8(lsb))
 
-	return val
+	return (msb << 8) | lsb
 }
 
-// Ignore the CRC for now
-func (sht *SHT1x) skipCRC() {
-	sht.dataPin.SetMode(gpio.ModeOutput)
-	sht.clockPin.SetMode(gpio.ModeOutput)
+// checkCRC acks the last data byte so the sensor sends its checksum,
+// reads that checksum byte, and compares it against the CRC-8 accumulated
+// over the command and data bytes by sendCommand/getData16. The sensor
+// transmits the checksum LSB-first, so the accumulator is bit-reversed
+// before the comparison.
+func (sht *SHT1x) checkCRC() error {
+	//// Ack the last data byte to request the checksum
+	sht.bus.Data().SetOutput()
+	sht.bus.Data().High()
+	sht.bus.Data().Low()
 
-	sht.dataPin.Set()
 	sht.clockTick(true)
 	sht.clockTick(false)
+
+	sht.bus.Data().SetInput()
+	got := uint8(sht.shiftIn(8))
+
+	//// No more bytes wanted: leave the ack line high
+	sht.bus.Data().SetOutput()
+	sht.bus.Data().High()
+	sht.clockTick(true)
+	sht.clockTick(false)
+
+	if want := reverseBits(sht.crc); got != want {
+		return &CRCError{Expected: want, Got: got}
+	}
+	return nil
 }
@@ -0,0 +1,98 @@
+package SHT1x_test
+
+import (
+	"testing"
+
+	sht1x "github.com/griffina/SHT1x"
+	"github.com/griffina/SHT1x/mock"
+)
+
+// crc8 and reverseBits are a standalone re-implementation of the
+// datasheet's checksum, kept independent of the driver's internal crc.go
+// so these tests build a canned reply rather than just re-running the
+// driver's own math.
+func crc8(crc, data uint8) uint8 {
+	crc ^= data
+	for i := 0; i < 8; i++ {
+		if crc&0x80 != 0 {
+			crc = (crc << 1) ^ 0x31
+		} else {
+			crc <<= 1
+		}
+	}
+	return crc
+}
+
+func reverseBits(b uint8) uint8 {
+	var r uint8
+	for i := 0; i < 8; i++ {
+		r <<= 1
+		r |= b & 1
+		b >>= 1
+	}
+	return r
+}
+
+// bitsMSBFirst splits the low n bits of v into individual bits, most
+// significant first, the order the driver clocks them in.
+func bitsMSBFirst(v uint16, n int) []bool {
+	bits := make([]bool, n)
+	for i := 0; i < n; i++ {
+		bits[i] = v&(1<<uint(n-1-i)) != 0
+	}
+	return bits
+}
+
+const tempCmd = 3
+
+// queueTemperatureReply primes bus with a canned response to a
+// ReadTemperature 14-bit measurement command, with either a matching or
+// deliberately broken checksum. The sensor sends the measurement as two
+// full 8-bit bytes (MSB, then LSB), whatever the resolution.
+func queueTemperatureReply(bus *mock.Bus, raw uint16, goodCRC bool) {
+	msb, lsb := uint8(raw>>8), uint8(raw&0xff)
+
+	crc := crc8(reverseBits(0), tempCmd)
+	crc = crc8(crc, msb)
+	crc = crc8(crc, lsb)
+	crcByte := reverseBits(crc)
+	if !goodCRC {
+		crcByte ^= 0xff
+	}
+
+	data := bus.Data().(*mock.Pin)
+	data.Queue(false, true) // sendCommand's two acks
+	data.Queue(false)       // waitForResult: measurement ready
+	data.Queue(bitsMSBFirst(uint16(msb), 8)...)
+	data.Queue(bitsMSBFirst(uint16(lsb), 8)...)
+	data.Queue(bitsMSBFirst(uint16(crcByte), 8)...)
+}
+
+func TestReadTemperatureValidCRC(t *testing.T) {
+	bus := mock.New()
+	sht := sht1x.New(bus)
+
+	raw := uint16(0x2000) // arbitrary 14-bit measurement
+	queueTemperatureReply(bus, raw, true)
+
+	temp, err := sht.ReadTemperature()
+	if err != nil {
+		t.Fatalf("ReadTemperature: %v", err)
+	}
+	want := float32(raw)*0.01 - 40.1
+	if temp != want {
+		t.Errorf("temp = %v, want %v", temp, want)
+	}
+}
+
+func TestReadTemperatureBadCRC(t *testing.T) {
+	bus := mock.New()
+	sht := sht1x.New(bus)
+
+	queueTemperatureReply(bus, 0x2000, false)
+
+	_, err := sht.ReadTemperature()
+	if _, ok := err.(*sht1x.CRCError); !ok {
+		t.Fatalf("ReadTemperature err = %v, want *sht1x.CRCError", err)
+	}
+}
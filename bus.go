@@ -0,0 +1,23 @@
+package SHT1x
+
+// Pin is the single GPIO line the SHT1x bit-banging protocol needs:
+// direction control plus level read/write. Implementations live in
+// subpackages such as rpi (real hardware) and mock (tests).
+type Pin interface {
+	SetInput()
+	SetOutput()
+	High()
+	Low()
+	Read() bool
+}
+
+// Bus supplies the two GPIO lines (data and clock) and the protocol
+// timing the sensor needs. DelayShort is one clock half-period;
+// DelayLong is the interval waitForResult polls at while a measurement
+// is in progress.
+type Bus interface {
+	Data() Pin
+	Clock() Pin
+	DelayShort()
+	DelayLong()
+}
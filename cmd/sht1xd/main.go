@@ -0,0 +1,49 @@
+// Command sht1xd samples an SHT1x/SHT7x sensor on a Raspberry Pi and
+// serves the readings over HTTP: Prometheus metrics at /metrics and the
+// latest sample as JSON at /reading.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	sht1x "github.com/griffina/SHT1x"
+	"github.com/griffina/SHT1x/exporter"
+	"github.com/griffina/SHT1x/rpi"
+)
+
+func main() {
+	dataPin := flag.Int("data-pin", 0, "GPIO pin connected to the sensor's DATA line")
+	clockPin := flag.Int("clock-pin", 0, "GPIO pin connected to the sensor's SCK line")
+	addr := flag.String("addr", ":9162", "address to serve /metrics and /reading on")
+	interval := flag.Duration("interval", 10*time.Second, "sampling interval")
+	flag.Parse()
+
+	bus, err := rpi.New(*dataPin, *clockPin)
+	if err != nil {
+		log.Fatalln("opening GPIO pins:", err)
+	}
+	sht := sht1x.New(bus)
+	exp := exporter.New(sht, *interval)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	go exp.Run(ctx)
+
+	server := &http.Server{Addr: *addr, Handler: exp.Handler()}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	log.Println("sht1xd listening on", *addr)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,44 @@
+package SHT1x
+
+import "fmt"
+
+// CRCError reports that the checksum byte read back from the sensor did
+// not match the one computed from the command and data bytes. Seeing
+// this repeatedly usually means a noisy or too-long wire run to the
+// sensor.
+type CRCError struct {
+	Expected uint8
+	Got      uint8
+}
+
+func (e *CRCError) Error() string {
+	return fmt.Sprintf("SHT1x: CRC mismatch, expected %#02x got %#02x", e.Expected, e.Got)
+}
+
+// crc8 folds one byte into a running CRC-8 accumulator using the
+// polynomial x^8 + x^5 + x^4 + 1 (0x31) specified by the Sensirion
+// SHT1x/SHT7x datasheet.
+func crc8(crc, data uint8) uint8 {
+	crc ^= data
+	for i := 0; i < 8; i++ {
+		if crc&0x80 != 0 {
+			crc = (crc << 1) ^ 0x31
+		} else {
+			crc <<= 1
+		}
+	}
+	return crc
+}
+
+// reverseBits reverses the bit order of a byte. The sensor transmits its
+// status register and CRC LSB-first, so both the CRC seed and the final
+// accumulator need reversing before they line up with the wire order.
+func reverseBits(b uint8) uint8 {
+	var r uint8
+	for i := 0; i < 8; i++ {
+		r <<= 1
+		r |= b & 1
+		b >>= 1
+	}
+	return r
+}
@@ -0,0 +1,26 @@
+package SHT1x
+
+import "math"
+
+// ReadDewPoint reads temperature and humidity from the sensor and
+// returns the dew point in °C, computed with the Magnus formula. It
+// returns an error rather than a bare float32 so a failed or
+// CRC-mismatched read is reported the same way as ReadTemperature and
+// ReadHumidity, instead of silently handing back a dew point computed
+// from a zeroed reading.
+func (sht *SHT1x) ReadDewPoint() (float32, error) {
+	_, _, dewPoint, err := sht.ReadTempAndHumidity()
+	return dewPoint, err
+}
+
+// dewPointFromTempAndHumidity computes the dew point in °C from a
+// temperature (°C) and relative humidity (%) reading, using the Magnus
+// formula.
+func dewPointFromTempAndHumidity(temp, humidity float32) float32 {
+	t := float64(temp)
+	rh := float64(humidity)
+
+	logEx := 0.66077 + 7.5*t/(237.3+t) + math.Log10(rh) - 2
+	dewPoint := (logEx - 0.66077) * 237.3 / (0.66077 + 7.5 - logEx)
+	return float32(dewPoint)
+}
@@ -0,0 +1,143 @@
+// Package exporter samples an *sht1x.SHT1x on a timer and serves the
+// readings over HTTP: Prometheus metrics at /metrics and the latest
+// sample as JSON at /reading.
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	sht1x "github.com/griffina/SHT1x"
+)
+
+// MinInterval is the shortest sampling interval Exporter accepts. The
+// SHT1x/SHT7x datasheet recommends keeping the sensor's self-heating duty
+// cycle under 10%; at worst-case measurement times that works out to
+// roughly one reading every 2s.
+const MinInterval = 2 * time.Second
+
+// Reading is the latest sample taken from the sensor, served as JSON at
+// /reading.
+type Reading struct {
+	Temperature float32   `json:"temperature_celsius"`
+	Humidity    float32   `json:"humidity_relative"`
+	DewPoint    float32   `json:"dew_point_celsius"`
+	Time        time.Time `json:"time"`
+}
+
+// Exporter periodically reads a sensor and serves the readings over
+// HTTP. Create one with New and start sampling with Run.
+type Exporter struct {
+	sht      *sht1x.SHT1x
+	interval time.Duration
+	registry *prometheus.Registry
+
+	mu      sync.RWMutex
+	reading Reading
+
+	temperature prometheus.Gauge
+	humidity    prometheus.Gauge
+	dewPoint    prometheus.Gauge
+	readErrors  prometheus.Counter
+	crcErrors   prometheus.Counter
+}
+
+// New creates an Exporter sampling sht every interval. interval is
+// raised to MinInterval if lower.
+func New(sht *sht1x.SHT1x, interval time.Duration) *Exporter {
+	if interval < MinInterval {
+		interval = MinInterval
+	}
+
+	e := &Exporter{
+		sht:      sht,
+		interval: interval,
+		registry: prometheus.NewRegistry(),
+
+		temperature: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "sht1x_temperature_celsius",
+			Help: "Last temperature reading from the sensor, in °C.",
+		}),
+		humidity: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "sht1x_humidity_relative",
+			Help: "Last relative humidity reading from the sensor, in %.",
+		}),
+		dewPoint: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "sht1x_dew_point_celsius",
+			Help: "Last dew point reading from the sensor, in °C.",
+		}),
+		readErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "sht1x_read_errors_total",
+			Help: "Total number of failed sensor reads, including CRC mismatches.",
+		}),
+		crcErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "sht1x_crc_errors_total",
+			Help: "Total number of sensor reads that failed CRC validation.",
+		}),
+	}
+
+	e.registry.MustRegister(e.temperature, e.humidity, e.dewPoint, e.readErrors, e.crcErrors)
+	return e
+}
+
+// Run samples the sensor immediately and then every interval, until ctx
+// is done.
+func (e *Exporter) Run(ctx context.Context) {
+	e.sample(ctx)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.sample(ctx)
+		}
+	}
+}
+
+func (e *Exporter) sample(ctx context.Context) {
+	temp, humidity, dewPoint, err := e.sht.ReadTempAndHumidityCtx(ctx)
+	if err != nil {
+		e.readErrors.Inc()
+		if _, ok := err.(*sht1x.CRCError); ok {
+			e.crcErrors.Inc()
+		}
+		log.Println("sht1x exporter: read failed:", err)
+		return
+	}
+
+	e.temperature.Set(float64(temp))
+	e.humidity.Set(float64(humidity))
+	e.dewPoint.Set(float64(dewPoint))
+
+	e.mu.Lock()
+	e.reading = Reading{Temperature: temp, Humidity: humidity, DewPoint: dewPoint, Time: time.Now()}
+	e.mu.Unlock()
+}
+
+// Handler serves /metrics (Prometheus) and /reading (JSON).
+func (e *Exporter) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/reading", e.serveReading)
+	return mux
+}
+
+func (e *Exporter) serveReading(w http.ResponseWriter, r *http.Request) {
+	e.mu.RLock()
+	reading := e.reading
+	e.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reading)
+}
@@ -0,0 +1,47 @@
+package exporter_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sht1x "github.com/griffina/SHT1x"
+	"github.com/griffina/SHT1x/exporter"
+	"github.com/griffina/SHT1x/mock"
+)
+
+func TestExporterServesReadingAndMetrics(t *testing.T) {
+	bus := mock.New()
+	sht := sht1x.New(bus)
+	exp := exporter.New(sht, exporter.MinInterval)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	exp.Run(ctx) // one immediate sample, then returns once ctx is done
+
+	server := httptest.NewServer(exp.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/reading")
+	if err != nil {
+		t.Fatalf("GET /reading: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var reading exporter.Reading
+	if err := json.NewDecoder(resp.Body).Decode(&reading); err != nil {
+		t.Fatalf("decoding /reading: %v", err)
+	}
+
+	resp, err = http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /metrics: status %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,75 @@
+// Package mock provides an in-memory sht1x.Bus for exercising the SHT1x
+// driver's protocol handling (CRC, resolution switching, timeouts...) in
+// tests, without real hardware or root.
+package mock
+
+import sht1x "github.com/griffina/SHT1x"
+
+// Pin is an in-memory sht1x.Pin. Queue supplies the levels it will
+// return from Read while acting as an input (as a canned sensor
+// response would produce); Written returns every level the driver wrote
+// while it was an output.
+type Pin struct {
+	output bool
+	level  bool
+
+	queued  []bool
+	written []bool
+}
+
+func (p *Pin) SetInput()  { p.output = false }
+func (p *Pin) SetOutput() { p.output = true }
+
+func (p *Pin) High() { p.set(true) }
+func (p *Pin) Low()  { p.set(false) }
+
+func (p *Pin) set(level bool) {
+	p.level = level
+	if p.output {
+		p.written = append(p.written, level)
+	}
+}
+
+// Read returns the next queued level, or the last level written to the
+// pin if nothing is queued.
+func (p *Pin) Read() bool {
+	if len(p.queued) == 0 {
+		return p.level
+	}
+	level := p.queued[0]
+	p.queued = p.queued[1:]
+	return level
+}
+
+// Queue appends levels to be returned by successive Read calls, the way
+// a canned sensor response would drive the line.
+func (p *Pin) Queue(levels ...bool) {
+	p.queued = append(p.queued, levels...)
+}
+
+// Written returns every level written to the pin while in output mode,
+// in order, and clears the log.
+func (p *Pin) Written() []bool {
+	w := p.written
+	p.written = nil
+	return w
+}
+
+// Bus is an in-memory sht1x.Bus wiring two mock Pins together.
+type Bus struct {
+	data  Pin
+	clock Pin
+}
+
+// New returns a ready-to-use mock Bus.
+func New() *Bus {
+	return &Bus{}
+}
+
+func (b *Bus) Data() sht1x.Pin  { return &b.data }
+func (b *Bus) Clock() sht1x.Pin { return &b.clock }
+
+// DelayShort and DelayLong are no-ops: there's no real bus timing to
+// respect in memory.
+func (b *Bus) DelayShort() {}
+func (b *Bus) DelayLong()  {}
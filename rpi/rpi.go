@@ -0,0 +1,57 @@
+// Package rpi implements an SHT1x.Bus on top of Raspberry Pi GPIO pins,
+// using github.com/griffina/gpio the way the top-level package talked to
+// the sensor directly before it became pluggable.
+package rpi
+
+import (
+	"time"
+
+	"github.com/griffina/gpio"
+	_ "github.com/griffina/gpio/rpi"
+
+	sht1x "github.com/griffina/SHT1x"
+)
+
+// pin adapts a gpio.Pin to sht1x.Pin.
+type pin struct {
+	gpio.Pin
+}
+
+func (p pin) SetInput()  { p.Pin.SetMode(gpio.ModeInput) }
+func (p pin) SetOutput() { p.Pin.SetMode(gpio.ModeOutput) }
+func (p pin) High()      { p.Pin.Set() }
+func (p pin) Low()       { p.Pin.Clear() }
+func (p pin) Read() bool { return p.Pin.Get() }
+
+// Bus is an sht1x.Bus backed by two Raspberry Pi GPIO pins.
+type Bus struct {
+	data  pin
+	clock pin
+}
+
+// New opens the data and clock GPIO pins and returns a Bus connecting
+// them to an SHT1x/SHT7x sensor.
+func New(dataPin, clockPin int) (*Bus, error) {
+	d, err := gpio.OpenPin(dataPin, gpio.ModeOutput)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := gpio.OpenPin(clockPin, gpio.ModeOutput)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Bus{data: pin{d}, clock: pin{c}}, nil
+}
+
+func (b *Bus) Data() sht1x.Pin  { return b.data }
+func (b *Bus) Clock() sht1x.Pin { return b.clock }
+
+// DelayShort is one clock half-period, matching the original driver's
+// clockTick delay.
+func (b *Bus) DelayShort() { time.Sleep(100 * time.Nanosecond) }
+
+// DelayLong is the poll interval used while waiting for a measurement,
+// matching the original driver's waitForResult delay.
+func (b *Bus) DelayLong() { time.Sleep(10 * time.Millisecond) }
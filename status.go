@@ -0,0 +1,97 @@
+package SHT1x
+
+import "log"
+
+// Status register bits, from the datasheet.
+const (
+	statusLowResolution uint8 = 1 << 0 // 12 bit temp / 8 bit RH instead of 14/12
+	statusNoReload      uint8 = 1 << 1 // skip reloading calibration data from OTP
+	statusHeater        uint8 = 1 << 2 // on-chip heater, for self-test
+	statusLowBattery    uint8 = 1 << 6 // read-only, set when VDD < 2.47V
+)
+
+// ReadStatusRegister reads the sensor's status register (command 0x07).
+func (sht *SHT1x) ReadStatusRegister() (uint8, error) {
+	sht.mu.Lock()
+	defer sht.mu.Unlock()
+
+	sht.sendCommand(readStatusCmd)
+	val := uint8(sht.getData16(8))
+	if err := sht.checkCRC(); err != nil {
+		return 0, err
+	}
+	sht.statusReg = val
+	return val, nil
+}
+
+// WriteStatusRegister writes a new value to the sensor's status register
+// (command 0x06). SetHeater, SetLowResolution and SetNoReload are
+// convenience wrappers around this for the individual bits.
+func (sht *SHT1x) WriteStatusRegister(val uint8) error {
+	sht.mu.Lock()
+	defer sht.mu.Unlock()
+	return sht.writeStatusRegister(val)
+}
+
+func (sht *SHT1x) writeStatusRegister(val uint8) error {
+	sht.sendCommand(writeStatusCmd)
+
+	sht.bus.Data().SetOutput()
+	sht.writeByte(val)
+
+	sht.bus.Data().SetInput()
+	if ack := sht.bus.Data().Read(); ack != false {
+		log.Println("Nack, in write status register")
+	}
+
+	sht.statusReg = val
+	return nil
+}
+
+// SetHeater turns the sensor's on-chip heater on or off. It's mainly
+// useful as a self-test: with the heater on, a follow-up read should show
+// a rising temperature and a falling humidity.
+func (sht *SHT1x) SetHeater(enabled bool) error {
+	return sht.setStatusBit(statusHeater, enabled)
+}
+
+// SetLowResolution switches between the sensor's default 14-bit
+// temperature / 12-bit humidity measurements and its low-power 12-bit /
+// 8-bit mode.
+func (sht *SHT1x) SetLowResolution(enabled bool) error {
+	return sht.setStatusBit(statusLowResolution, enabled)
+}
+
+// SetNoReload disables reloading calibration data from OTP before each
+// measurement, trading a little accuracy for faster reads.
+func (sht *SHT1x) SetNoReload(enabled bool) error {
+	return sht.setStatusBit(statusNoReload, enabled)
+}
+
+func (sht *SHT1x) setStatusBit(bit uint8, enabled bool) error {
+	sht.mu.Lock()
+	defer sht.mu.Unlock()
+
+	val := sht.statusReg
+	if enabled {
+		val |= bit
+	} else {
+		val &^= bit
+	}
+	return sht.writeStatusRegister(val)
+}
+
+// BatteryLow reports the sensor's VDD<2.47V status bit.
+func (sht *SHT1x) BatteryLow() (bool, error) {
+	val, err := sht.ReadStatusRegister()
+	if err != nil {
+		return false, err
+	}
+	return val&statusLowBattery != 0, nil
+}
+
+// lowResolution reports whether the status register's resolution bit is
+// currently set.
+func (sht *SHT1x) lowResolution() bool {
+	return sht.statusReg&statusLowResolution != 0
+}
@@ -0,0 +1,17 @@
+package SHT1x
+
+import "fmt"
+
+// TimeoutError reports that a bus operation did not complete before its
+// context was cancelled or its deadline passed, or that the sensor did
+// not signal a ready measurement within waitForResult's 100 polls (one
+// Bus.DelayLong apart) even with a live context. Read*Ctx callers that see
+// this can retry; the sensor has already been issued a connection-reset
+// sequence so the next attempt starts from a known state.
+type TimeoutError struct {
+	Op string
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("SHT1x: timed out %s", e.Op)
+}
@@ -0,0 +1,32 @@
+package SHT1x
+
+// Voltage identifies the sensor's supply voltage. The temperature
+// formula's offset coefficient (d1) is specified per-VDD in the
+// datasheet, so it must be selected at construction time via
+// NewWithVoltage.
+type Voltage int
+
+const (
+	VDD_5V Voltage = iota
+	VDD_4V
+	VDD_3_5V
+	VDD_3V
+	VDD_2_5V
+)
+
+// d1ForVoltage returns the temperature offset coefficient for the given
+// supply voltage, from the datasheet's d1 table.
+func d1ForVoltage(v Voltage) float32 {
+	switch v {
+	case VDD_4V:
+		return -39.8
+	case VDD_3_5V:
+		return -39.7
+	case VDD_3V:
+		return -39.6
+	case VDD_2_5V:
+		return -39.4
+	default:
+		return -40.1 // VDD_5V
+	}
+}